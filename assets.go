@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AssetUploader is implemented by backends that can host binary assets
+// (images, PDFs, etc.) referenced from Markdown. Backends that don't
+// implement it (e.g. FilesystemBackend, DryRunBackend) simply skip asset
+// rewriting, since their ImportDocument already has access to the files on
+// disk relative to the Markdown source.
+type AssetUploader interface {
+	// UploadAsset uploads the file at path and returns the URL it should
+	// be referenced by from Markdown.
+	UploadAsset(path string) (url string, err error)
+}
+
+// linkRef is a single `](...)` span found in a Markdown file: the byte
+// range of its target (exclusive of the parens) and the target text.
+type linkRef struct {
+	start, end int // byte offsets of target within the source, for the closing ")"
+	target     string
+}
+
+// findLinkRefs scans content for Markdown link/image targets (`](target)`),
+// matching parens by depth rather than stopping at the first ")" so
+// filenames like "diagram(2).png" aren't truncated. A target is abandoned
+// if its parens don't balance before the end of the line.
+func findLinkRefs(content string) []linkRef {
+	var refs []linkRef
+	for idx := 0; ; {
+		i := strings.Index(content[idx:], "](")
+		if i < 0 {
+			break
+		}
+		start := idx + i + 2
+		depth := 1
+		j := start
+		for ; j < len(content) && content[j] != '\n'; j++ {
+			switch content[j] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					refs = append(refs, linkRef{start: start, end: j, target: content[start:j]})
+				}
+			}
+			if depth == 0 {
+				break
+			}
+		}
+		if j <= start {
+			j = start + 1
+		} else {
+			j++ // advance past the closing ")" (or the newline that aborted the scan)
+		}
+		idx = j
+	}
+	return refs
+}
+
+// ParseAssetExtensions splits a comma-separated list like "png,jpg,gif,pdf"
+// into a lookup set of lowercase extensions (without the leading dot).
+func ParseAssetExtensions(list string) map[string]bool {
+	exts := make(map[string]bool)
+	for _, e := range strings.Split(list, ",") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		e = strings.TrimPrefix(e, ".")
+		if e != "" {
+			exts[e] = true
+		}
+	}
+	return exts
+}
+
+// linkTarget strips an optional Markdown title (`url "title"`) off a link
+// target and returns the bare reference. A space alone isn't enough to
+// mark a title, since local asset filenames frequently contain spaces
+// (e.g. screenshot exports) — only a quote immediately following the space
+// is treated as the start of a title.
+func linkTarget(raw string) string {
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != ' ' && raw[i] != '\t' {
+			continue
+		}
+		rest := strings.TrimLeft(raw[i:], " \t")
+		if len(rest) > 0 && (rest[0] == '"' || rest[0] == '\'') {
+			return raw[:i]
+		}
+	}
+	return raw
+}
+
+// isLocalAssetRef reports whether target looks like a relative path to a
+// local file with one of the given extensions, as opposed to a remote URL
+// or an anchor link.
+func isLocalAssetRef(target string, extensions map[string]bool) bool {
+	if target == "" || strings.HasPrefix(target, "#") {
+		return false
+	}
+	if strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+		return false
+	}
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(target), "."))
+	return extensions[ext]
+}
+
+// RewriteAssetLinks scans the Markdown file at mdPath for local asset
+// references (images, links) whose extension is in extensions, uploads
+// each unique one via uploader, and rewrites the references to the
+// returned URLs. Each matched span is replaced by its own byte offsets
+// rather than a blind find-and-replace, so identical text elsewhere in the
+// file (e.g. inside a fenced code sample) isn't touched. If any reference
+// was rewritten, it writes the result to a new temp file and returns its
+// path plus a cleanup func to remove it; otherwise it returns mdPath
+// unchanged and a no-op cleanup.
+func RewriteAssetLinks(mdPath string, extensions map[string]bool, uploader AssetUploader) (importPath string, cleanup func(), err error) {
+	noop := func() {}
+
+	data, err := os.ReadFile(mdPath)
+	if err != nil {
+		return "", noop, fmt.Errorf("reading %q: %w", mdPath, err)
+	}
+	content := string(data)
+	baseDir := filepath.Dir(mdPath)
+
+	uploaded := make(map[string]string) // bare target -> uploaded URL, for dedup across occurrences
+	var toReplace []linkRef
+	for _, ref := range findLinkRefs(content) {
+		target := linkTarget(ref.target)
+		url, ok := uploaded[target]
+		if !ok {
+			if !isLocalAssetRef(target, extensions) {
+				continue
+			}
+			assetPath := filepath.Join(baseDir, filepath.FromSlash(target))
+			if _, statErr := os.Stat(assetPath); statErr != nil {
+				continue // reference doesn't resolve to a local file; leave as-is
+			}
+			url, err = uploader.UploadAsset(assetPath)
+			if err != nil {
+				return "", noop, fmt.Errorf("uploading asset %q: %w", assetPath, err)
+			}
+			uploaded[target] = url
+		}
+		toReplace = append(toReplace, linkRef{start: ref.start, end: ref.end, target: url})
+	}
+	if len(toReplace) == 0 {
+		return mdPath, noop, nil
+	}
+
+	var b strings.Builder
+	prev := 0
+	for _, ref := range toReplace {
+		b.WriteString(content[prev:ref.start])
+		b.WriteString(ref.target)
+		prev = ref.end
+	}
+	b.WriteString(content[prev:])
+	content = b.String()
+
+	tmp, err := os.CreateTemp("", "outline-import-*.md")
+	if err != nil {
+		return "", noop, fmt.Errorf("creating temp file: %w", err)
+	}
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", noop, fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", noop, fmt.Errorf("closing temp file: %w", err)
+	}
+	return tmp.Name(), func() { os.Remove(tmp.Name()) }, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fakeUploader struct {
+	uploaded []string
+}
+
+func (u *fakeUploader) UploadAsset(path string) (string, error) {
+	u.uploaded = append(u.uploaded, path)
+	return "https://example.com/attachments/" + filepath.Base(path), nil
+}
+
+func TestRewriteAssetLinks_UploadsLocalImagesOnce(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "foo.png"), []byte("fake-png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mdPath := filepath.Join(dir, "doc.md")
+	src := "# Title\n\n![alt](./foo.png)\n\nSee also ![again](./foo.png) and [remote](https://example.com/x.png).\n"
+	if err := os.WriteFile(mdPath, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	uploader := &fakeUploader{}
+	outPath, cleanup, err := RewriteAssetLinks(mdPath, ParseAssetExtensions("png,jpg"), uploader)
+	if err != nil {
+		t.Fatalf("RewriteAssetLinks returned error: %v", err)
+	}
+	defer cleanup()
+
+	if len(uploader.uploaded) != 1 {
+		t.Fatalf("expected exactly one upload, got %d: %v", len(uploader.uploaded), uploader.uploaded)
+	}
+
+	out, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewritten := string(out)
+	if strings.Contains(rewritten, "./foo.png") {
+		t.Fatalf("local reference was not rewritten: %s", rewritten)
+	}
+	if !strings.Contains(rewritten, "https://example.com/attachments/foo.png") {
+		t.Fatalf("rewritten content missing uploaded URL: %s", rewritten)
+	}
+	if !strings.Contains(rewritten, "https://example.com/x.png") {
+		t.Fatalf("remote link should be left untouched: %s", rewritten)
+	}
+}
+
+func TestRewriteAssetLinks_FilenameWithSpacesAndParens(t *testing.T) {
+	dir := t.TempDir()
+	const name = "Screen Shot 2021-01-01 at 10.23 AM (2).png"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("fake-png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mdPath := filepath.Join(dir, "doc.md")
+	src := "![shot](" + name + ")\n"
+	if err := os.WriteFile(mdPath, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	uploader := &fakeUploader{}
+	outPath, cleanup, err := RewriteAssetLinks(mdPath, ParseAssetExtensions("png"), uploader)
+	if err != nil {
+		t.Fatalf("RewriteAssetLinks returned error: %v", err)
+	}
+	defer cleanup()
+
+	if len(uploader.uploaded) != 1 {
+		t.Fatalf("expected exactly one upload, got %d: %v", len(uploader.uploaded), uploader.uploaded)
+	}
+	out, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "]("+name+")") {
+		t.Fatalf("reference with spaces/parens was not rewritten: %s", out)
+	}
+}
+
+func TestRewriteAssetLinks_NoLocalAssets(t *testing.T) {
+	dir := t.TempDir()
+	mdPath := filepath.Join(dir, "doc.md")
+	src := "# Title\n\nNo images here, just [a link](https://example.com).\n"
+	if err := os.WriteFile(mdPath, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	uploader := &fakeUploader{}
+	outPath, cleanup, err := RewriteAssetLinks(mdPath, ParseAssetExtensions("png,jpg"), uploader)
+	if err != nil {
+		t.Fatalf("RewriteAssetLinks returned error: %v", err)
+	}
+	defer cleanup()
+
+	if outPath != mdPath {
+		t.Fatalf("expected original path to be returned unchanged, got %s", outPath)
+	}
+	if len(uploader.uploaded) != 0 {
+		t.Fatalf("expected no uploads, got %v", uploader.uploaded)
+	}
+}
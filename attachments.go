@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CreateAttachmentResponse represents the response from
+// /api/attachments.create: a presigned URL to PUT the file's bytes to, and
+// the attachment record (whose URL is what gets embedded in documents).
+type CreateAttachmentResponse struct {
+	Data struct {
+		UploadUrl  string `json:"uploadUrl"`
+		Attachment struct {
+			Id  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"attachment"`
+	} `json:"data"`
+	Ok bool `json:"ok"`
+}
+
+// UploadAttachment uploads the file at path via Outline's
+// /api/attachments.create + PUT-to-signed-URL flow and returns the URL to
+// reference it by in document Markdown.
+func (c *OutlineClient) UploadAttachment(path, host, token string) (string, error) {
+	start := time.Now()
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("stating %q: %w", path, err)
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	createURL := host + "/api/attachments.create"
+	payload := map[string]interface{}{
+		"name":        filepath.Base(path),
+		"contentType": contentType,
+		"size":        info.Size(),
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("marshalling payload: %w", err)
+	}
+
+	resp, err, attempts := c.pacer.Call(func() (*http.Response, error) {
+		req, err := http.NewRequest("POST", createURL, bytes.NewBuffer(payloadBytes))
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if debug {
+			log.Printf("Creating attachment for %s via %s", path, createURL)
+		}
+		return c.http.Do(req)
+	}, func(attempt int, reason string) {
+		if c.log != nil {
+			c.log.Retry(path, attempt, reason)
+		}
+	})
+	if err != nil {
+		return "", fmt.Errorf("executing attachments.create request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading attachments.create response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to create attachment for %s: %s", path, string(respBytes))
+	}
+
+	var createResp CreateAttachmentResponse
+	if err := json.Unmarshal(respBytes, &createResp); err != nil {
+		return "", fmt.Errorf("unmarshalling attachments.create response: %w", err)
+	}
+	if !createResp.Ok {
+		return "", fmt.Errorf("attachments.create not OK for %s: %s", path, string(respBytes))
+	}
+
+	putAttempts, err := c.putAttachment(createResp.Data.UploadUrl, path, contentType)
+	if err != nil {
+		return "", err
+	}
+
+	if c.log != nil {
+		c.log.AssetUploaded(path, time.Since(start), attempts+putAttempts)
+	}
+	return createResp.Data.Attachment.URL, nil
+}
+
+// putAttachment PUTs the file's bytes to the presigned uploadURL returned
+// by attachments.create, retrying through the pacer like every other
+// request the client makes. It returns the number of attempts made.
+func (c *OutlineClient) putAttachment(uploadURL, path, contentType string) (int, error) {
+	resp, err, attempts := c.pacer.Call(func() (*http.Response, error) {
+		// Rebuild the request on every attempt: a drained file reader can't
+		// be resent as-is, and the file may have changed size since it was
+		// last stat'd.
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("opening %q: %w", path, err)
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("stating %q: %w", path, err)
+		}
+
+		req, err := http.NewRequest("PUT", uploadURL, file)
+		if err != nil {
+			return nil, fmt.Errorf("creating PUT request: %w", err)
+		}
+		req.ContentLength = info.Size()
+		req.Header.Set("Content-Type", contentType)
+		return c.http.Do(req)
+	}, func(attempt int, reason string) {
+		if c.log != nil {
+			c.log.Retry(path, attempt, reason)
+		}
+	})
+	if err != nil {
+		return attempts, fmt.Errorf("uploading %q: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return attempts, fmt.Errorf("uploading %q: HTTP %d: %s", path, resp.StatusCode, string(body))
+	}
+	return attempts, nil
+}
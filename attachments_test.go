@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestOutlineClient() *OutlineClient {
+	return &OutlineClient{
+		http:  &http.Client{},
+		pacer: NewPacer(time.Millisecond, 5*time.Millisecond, 3),
+	}
+}
+
+func TestUploadAttachment_RetriesTransientPutFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "foo.png")
+	if err := os.WriteFile(path, []byte("fake-png-bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var putAttempts int32
+	mux := http.NewServeMux()
+	var uploadURL string
+	mux.HandleFunc("/api/attachments.create", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"data":{"uploadUrl":%q,"attachment":{"id":"att-1","url":"https://example.com/attachments/foo.png"}}}`, uploadURL)
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&putAttempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body := make([]byte, r.ContentLength)
+		if _, err := io.ReadFull(r.Body, body); err != nil {
+			t.Errorf("reading PUT body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	uploadURL = server.URL + "/upload"
+
+	client := newTestOutlineClient()
+	url, err := client.UploadAttachment(path, server.URL, "token")
+	if err != nil {
+		t.Fatalf("UploadAttachment returned error: %v", err)
+	}
+	if url != "https://example.com/attachments/foo.png" {
+		t.Fatalf("url = %q, want the attachment URL", url)
+	}
+	if got := atomic.LoadInt32(&putAttempts); got != 3 {
+		t.Fatalf("PUT attempts = %d, want 3 (two failures then a success)", got)
+	}
+}
+
+func TestUploadAttachment_PutFailsAfterMaxRetries(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "foo.png")
+	if err := os.WriteFile(path, []byte("fake-png-bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var putAttempts int32
+	mux := http.NewServeMux()
+	var uploadURL string
+	mux.HandleFunc("/api/attachments.create", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"data":{"uploadUrl":%q,"attachment":{"id":"att-1","url":"https://example.com/attachments/foo.png"}}}`, uploadURL)
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&putAttempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	uploadURL = server.URL + "/upload"
+
+	client := newTestOutlineClient()
+	if _, err := client.UploadAttachment(path, server.URL, "token"); err == nil {
+		t.Fatal("expected UploadAttachment to return an error after exhausting retries")
+	}
+	// maxRetries=3 means 1 initial attempt + 3 retries = 4 calls total.
+	if got := atomic.LoadInt32(&putAttempts); got != 4 {
+		t.Fatalf("PUT attempts = %d, want 4", got)
+	}
+}
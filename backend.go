@@ -0,0 +1,20 @@
+package main
+
+// Backend abstracts the destination an import writes to, so the walker in
+// main doesn't hard-code Outline's HTTP API. CreateFolder and
+// ImportDocument return/accept an opaque parent ID whose meaning is
+// entirely up to the implementation (an Outline document ID, a filesystem
+// path, a synthetic counter for dry runs, etc.) — callers only ever thread
+// it back in as parentID on a later call.
+type Backend interface {
+	// CreateFolder creates a "folder" representing name under parentID
+	// (the empty string for a top-level folder) and returns an opaque ID
+	// for it.
+	CreateFolder(name, parentID string) (id string, err error)
+	// ImportDocument imports the Markdown file at path under parentID
+	// (the empty string for no parent).
+	ImportDocument(path, parentID string) error
+	// ListCollections returns the destinations documents can be imported
+	// into, if the backend supports the concept.
+	ListCollections() ([]Collection, error)
+}
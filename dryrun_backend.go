@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+)
+
+// DryRunBackend logs what an import would do without making any network
+// calls or writing any files. It's useful for verifying the folder
+// structure Dropbox Paper exports will produce before hitting a real API.
+type DryRunBackend struct {
+	nextID int64
+}
+
+// NewDryRunBackend returns a Backend that only logs its calls.
+func NewDryRunBackend() *DryRunBackend {
+	return &DryRunBackend{}
+}
+
+func (b *DryRunBackend) CreateFolder(name, parentID string) (string, error) {
+	id := fmt.Sprintf("dry-run-folder-%d", atomic.AddInt64(&b.nextID, 1))
+	log.Printf("[dry-run] would create folder %q (parent: %s) -> %s", name, parentID, id)
+	return id, nil
+}
+
+func (b *DryRunBackend) ImportDocument(path, parentID string) error {
+	log.Printf("[dry-run] would import %q (parent: %s)", path, parentID)
+	return nil
+}
+
+func (b *DryRunBackend) ListCollections() ([]Collection, error) {
+	return nil, fmt.Errorf("dry-run backend has no collections to list")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// FilesystemBackend mirrors the source tree into a target directory on
+// disk instead of pushing to Outline. It's useful both for testing and for
+// users who want to reorganize a Paper export locally without pushing it
+// anywhere. Parent IDs are simply the target-side directory paths.
+type FilesystemBackend struct {
+	root string
+}
+
+// NewFilesystemBackend returns a Backend that writes into root, creating
+// it if necessary.
+func NewFilesystemBackend(root string) (*FilesystemBackend, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("creating target directory %q: %w", root, err)
+	}
+	return &FilesystemBackend{root: root}, nil
+}
+
+func (b *FilesystemBackend) CreateFolder(name, parentID string) (string, error) {
+	parent := parentID
+	if parent == "" {
+		parent = b.root
+	}
+	dir := filepath.Join(parent, name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("creating folder %q: %w", dir, err)
+	}
+	return dir, nil
+}
+
+func (b *FilesystemBackend) ImportDocument(path, parentID string) error {
+	parent := parentID
+	if parent == "" {
+		parent = b.root
+	}
+	dest := filepath.Join(parent, filepath.Base(path))
+
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening %q: %w", path, err)
+	}
+	defer src.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("creating %q: %w", dest, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, src); err != nil {
+		return fmt.Errorf("copying %q to %q: %w", path, dest, err)
+	}
+	return nil
+}
+
+func (b *FilesystemBackend) ListCollections() ([]Collection, error) {
+	return nil, fmt.Errorf("filesystem backend has no collections to list")
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FolderCreator resolves relative folder paths to backend folder IDs,
+// creating folders on demand. It is safe for concurrent use: folderMap is
+// guarded by mu, and pathLocks ensures that two goroutines racing to create
+// the same path serialize instead of creating duplicates.
+type FolderCreator struct {
+	backend Backend
+	state   *StateStore // optional; nil if -state was not provided
+
+	mu        sync.Mutex
+	folderMap map[string]string // relative folder path -> backend folder ID
+	pathLocks sync.Map          // relative folder path -> *sync.Mutex
+}
+
+// NewFolderCreator returns a FolderCreator backed by the given Backend. If
+// state is non-nil, it is used to seed already-created folders and is
+// updated every time a new folder is created.
+func NewFolderCreator(backend Backend, state *StateStore) *FolderCreator {
+	fc := &FolderCreator{
+		backend:   backend,
+		state:     state,
+		folderMap: make(map[string]string),
+	}
+	if state != nil {
+		fc.folderMap = state.Folders()
+	}
+	return fc
+}
+
+func (fc *FolderCreator) get(path string) (string, bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	id, ok := fc.folderMap[path]
+	return id, ok
+}
+
+func (fc *FolderCreator) set(path, id string) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.folderMap[path] = id
+}
+
+// lockPath returns the mutex used to serialize creation of a single
+// relative folder path, creating it lazily.
+func (fc *FolderCreator) lockPath(path string) *sync.Mutex {
+	l, _ := fc.pathLocks.LoadOrStore(path, &sync.Mutex{})
+	return l.(*sync.Mutex)
+}
+
+// GetOrCreate returns the backend folder ID for the given relative folder
+// path, creating folders for each path segment that doesn't exist yet.
+// Concurrent calls for the same or overlapping paths are serialized per
+// segment so siblings never race to create the same parent.
+func (fc *FolderCreator) GetOrCreate(relPath string) (string, error) {
+	if relPath == "" || relPath == "." {
+		return "", nil
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	if id, ok := fc.get(relPath); ok {
+		return id, nil
+	}
+
+	segments := strings.Split(relPath, "/")
+	var currentPath string
+	var parentID string
+	for _, seg := range segments {
+		if currentPath == "" {
+			currentPath = seg
+		} else {
+			currentPath = currentPath + "/" + seg
+		}
+
+		segLock := fc.lockPath(currentPath)
+		segLock.Lock()
+		id, exists := fc.get(currentPath)
+		if exists {
+			segLock.Unlock()
+			parentID = id
+			continue
+		}
+
+		newID, err := fc.backend.CreateFolder(seg, parentID)
+		if err != nil {
+			segLock.Unlock()
+			return "", fmt.Errorf("creating folder %q: %w", currentPath, err)
+		}
+		fc.set(currentPath, newID)
+		if fc.state != nil {
+			if err := fc.state.SetFolder(currentPath, newID); err != nil {
+				segLock.Unlock()
+				return "", fmt.Errorf("persisting state for folder %q: %w", currentPath, err)
+			}
+		}
+		segLock.Unlock()
+		parentID = newID
+	}
+	return parentID, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// memoryBackend is an in-memory Backend used to assert folder-creation
+// order and parentage without touching the network or disk.
+type memoryBackend struct {
+	created []string // folder names in the order CreateFolder was called
+	parents map[string]string
+	nextID  int
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{parents: make(map[string]string)}
+}
+
+func (b *memoryBackend) CreateFolder(name, parentID string) (string, error) {
+	b.created = append(b.created, name)
+	b.nextID++
+	id := name
+	b.parents[id] = parentID
+	return id, nil
+}
+
+func (b *memoryBackend) ImportDocument(path, parentID string) error { return nil }
+
+func (b *memoryBackend) ListCollections() ([]Collection, error) { return nil, nil }
+
+func TestFolderCreatorGetOrCreate_CreatesEachSegmentOnce(t *testing.T) {
+	backend := newMemoryBackend()
+	fc := NewFolderCreator(backend, nil)
+
+	id, err := fc.GetOrCreate("a/b/c")
+	if err != nil {
+		t.Fatalf("GetOrCreate returned error: %v", err)
+	}
+	if id != "c" {
+		t.Fatalf("expected leaf folder ID %q, got %q", "c", id)
+	}
+
+	wantOrder := []string{"a", "b", "c"}
+	if !reflect.DeepEqual(backend.created, wantOrder) {
+		t.Fatalf("creation order = %v, want %v", backend.created, wantOrder)
+	}
+	if backend.parents["b"] != "a" || backend.parents["c"] != "b" {
+		t.Fatalf("unexpected parent chain: %v", backend.parents)
+	}
+
+	// Requesting an overlapping path must not recreate existing segments.
+	if _, err := fc.GetOrCreate("a/b/d"); err != nil {
+		t.Fatalf("GetOrCreate returned error: %v", err)
+	}
+	wantOrder = append(wantOrder, "d")
+	if !reflect.DeepEqual(backend.created, wantOrder) {
+		t.Fatalf("creation order after reuse = %v, want %v", backend.created, wantOrder)
+	}
+}
+
+func TestFolderCreatorGetOrCreate_EmptyPath(t *testing.T) {
+	fc := NewFolderCreator(newMemoryBackend(), nil)
+	id, err := fc.GetOrCreate("")
+	if err != nil || id != "" {
+		t.Fatalf("GetOrCreate(\"\") = (%q, %v), want (\"\", nil)", id, err)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// logEvent is the structured record emitted for every notable action during
+// an import run: a file imported, a folder created, a retry, or an error.
+type logEvent struct {
+	Event      string `json:"event"`
+	Path       string `json:"path,omitempty"`
+	ParentID   string `json:"parent_id,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	Attempt    int    `json:"attempt,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// EventLogger writes structured events (file_imported, folder_created,
+// retry, error) to w, either as human-readable text lines or as one JSON
+// object per line. It is safe for concurrent use. In -silent mode only
+// error events are written.
+type EventLogger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format string // "text" or "json"
+	silent bool
+}
+
+// NewEventLogger returns an EventLogger writing in the given format
+// ("text" or "json") to w. If silent is true, only error events are
+// written.
+func NewEventLogger(w io.Writer, format string, silent bool) *EventLogger {
+	return &EventLogger{w: w, format: format, silent: silent}
+}
+
+func (l *EventLogger) emit(e logEvent) {
+	if l.silent && e.Event != "error" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.format == "json" {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return
+		}
+		fmt.Fprintln(l.w, string(data))
+		return
+	}
+	line := e.Event
+	if e.Path != "" {
+		line += " path=" + e.Path
+	}
+	if e.ParentID != "" {
+		line += " parent_id=" + e.ParentID
+	}
+	if e.Attempt != 0 {
+		line += fmt.Sprintf(" attempt=%d", e.Attempt)
+	}
+	if e.DurationMs != 0 {
+		line += fmt.Sprintf(" duration_ms=%d", e.DurationMs)
+	}
+	if e.Error != "" {
+		line += " error=" + e.Error
+	}
+	fmt.Fprintln(l.w, line)
+}
+
+// FileImported logs a successful document import.
+func (l *EventLogger) FileImported(path, parentID string, d time.Duration, attempt int) {
+	l.emit(logEvent{Event: "file_imported", Path: path, ParentID: parentID, DurationMs: d.Milliseconds(), Attempt: attempt})
+}
+
+// FolderCreated logs a successful folder-document creation.
+func (l *EventLogger) FolderCreated(path, parentID string, d time.Duration, attempt int) {
+	l.emit(logEvent{Event: "folder_created", Path: path, ParentID: parentID, DurationMs: d.Milliseconds(), Attempt: attempt})
+}
+
+// Retry logs a single retried request.
+func (l *EventLogger) Retry(path string, attempt int, reason string) {
+	l.emit(logEvent{Event: "retry", Path: path, Attempt: attempt, Error: reason})
+}
+
+// Error logs a terminal failure for path.
+func (l *EventLogger) Error(path string, err error) {
+	l.emit(logEvent{Event: "error", Path: path, Error: err.Error()})
+}
+
+// AssetUploaded logs a successful attachment upload.
+func (l *EventLogger) AssetUploaded(path string, d time.Duration, attempt int) {
+	l.emit(logEvent{Event: "asset_uploaded", Path: path, DurationMs: d.Milliseconds(), Attempt: attempt})
+}
@@ -1,276 +1,171 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
 	"log"
-	"mime/multipart"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
 )
 
-// Collection represents a collection in Outline.
-type Collection struct {
-	Id          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-}
+var debug bool
 
-// CreateDocumentResponse represents the response from /api/documents.create.
-type CreateDocumentResponse struct {
-	Data struct {
-		Id string `json:"id"`
-	} `json:"data"`
-	Ok bool `json:"ok"`
+// fileJob is a single Markdown file queued for import.
+type fileJob struct {
+	path    string // absolute (or walk-rooted) path on disk
+	relPath string // path relative to the walk root
+	hash    string // sha256 hex digest of the file's content
 }
 
-// CollectionsResponse represents the response from /api/collections.list.
-type CollectionsResponse struct {
-	Data []Collection `json:"data"`
-	Ok   bool         `json:"ok"`
+// isMarkdownFile reports whether info names a Markdown file in a walk.
+func isMarkdownFile(info os.FileInfo) bool {
+	return !info.IsDir() && strings.HasSuffix(strings.ToLower(info.Name()), ".md")
 }
 
-var (
-	debug     bool
-	folderMap = make(map[string]string) // maps relative folder path to Outline document ID
-)
-
-// importMarkdownFile uploads a Markdown file to Outline using /api/documents.import.
-// The file is imported with the given parentDocumentId (if provided).
-func importMarkdownFile(filePath, collectionId, parentDocumentId, host, token string) error {
-	// Open the file.
-	file, err := os.Open(filePath)
-	if err != nil {
-		return fmt.Errorf("opening file: %w", err)
-	}
-	defer file.Close()
-
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
-
-	// Add the file field.
-	part, err := writer.CreateFormFile("file", filepath.Base(filePath))
-	if err != nil {
-		return fmt.Errorf("creating form file: %w", err)
-	}
-	if _, err := io.Copy(part, file); err != nil {
-		return fmt.Errorf("copying file content: %w", err)
-	}
-
-	// Add required fields.
-	if err := writer.WriteField("collectionId", collectionId); err != nil {
-		return fmt.Errorf("writing collectionId field: %w", err)
-	}
-	// Only add parentDocumentId if it's not empty.
-	if parentDocumentId != "" {
-		if err := writer.WriteField("parentDocumentId", parentDocumentId); err != nil {
-			return fmt.Errorf("writing parentDocumentId field: %w", err)
+// countMarkdownFiles walks folder and counts the Markdown files under it,
+// used to size the progress bar before the import pass begins.
+func countMarkdownFiles(folder string) (int, error) {
+	var n int
+	err := filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
 		}
-	}
-	if err := writer.WriteField("template", "false"); err != nil {
-		return fmt.Errorf("writing template field: %w", err)
-	}
-	if err := writer.WriteField("publish", "true"); err != nil {
-		return fmt.Errorf("writing publish field: %w", err)
-	}
-
-	// Finalize the multipart form.
-	if err := writer.Close(); err != nil {
-		return fmt.Errorf("closing writer: %w", err)
-	}
-
-	url := host + "/api/documents.import"
-	req, err := http.NewRequest("POST", url, &buf)
-	if err != nil {
-		return fmt.Errorf("creating HTTP request: %w", err)
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("Authorization", "Bearer "+token)
-
-	if debug {
-		log.Printf("Importing file: %s (parent: %s) to %s", filePath, parentDocumentId, url)
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("executing HTTP request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("reading response: %w", err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to import %s: %s", filePath, string(body))
-	}
-
-	if debug {
-		log.Printf("Imported file: %s, response: %s", filePath, string(body))
-	}
-	return nil
+		if isMarkdownFile(info) {
+			n++
+		}
+		return nil
+	})
+	return n, err
 }
 
-// createFolderDocument creates a "folder" document in Outline using /api/documents.create.
-// The folder is represented as a document with a title (folderName) and empty text.
-// Only include parentDocumentId if it's not empty.
-func createFolderDocument(folderName, collectionId, parentDocumentId, host, token string) (string, error) {
-	url := host + "/api/documents.create"
-
-	// Build payload. Only add parentDocumentId if provided.
-	payload := map[string]interface{}{
-		"collectionId": collectionId,
-		"title":        folderName,
-		"text":         "",
-		"template":     false,
-		"publish":      false,
-	}
-	if parentDocumentId != "" {
-		payload["parentDocumentId"] = parentDocumentId
-	}
-
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return "", fmt.Errorf("marshalling payload: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
-
-	if debug {
-		log.Printf("Creating folder document: %s (parent: %s) via %s", folderName, parentDocumentId, url)
+// importWorker pulls jobs off jobs until it's closed, creating any parent
+// folders needed and importing each file, sending a non-nil error to errs
+// for anything that fails. On success, if state is non-nil, the file's
+// content hash is recorded so a rerun can skip it. bar, if non-nil, is
+// incremented once per job regardless of outcome. If assetExtensions is
+// non-nil and backend implements AssetUploader, local asset references in
+// each file are uploaded and rewritten before import.
+func importWorker(jobs <-chan fileJob, errs chan<- error, fc *FolderCreator, state *StateStore, backend Backend, bar *pb.ProgressBar, assetExtensions map[string]bool) {
+	uploader, _ := backend.(AssetUploader)
+	for job := range jobs {
+		importJob(job, errs, fc, state, backend, uploader, assetExtensions)
+		if bar != nil {
+			bar.Increment()
+		}
 	}
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("executing request: %w", err)
+// importJob resolves job's parent folder, optionally rewrites local asset
+// references, and imports it, sending any failure to errs.
+func importJob(job fileJob, errs chan<- error, fc *FolderCreator, state *StateStore, backend Backend, uploader AssetUploader, assetExtensions map[string]bool) {
+	dir := filepath.Dir(job.relPath)
+	var parentID string
+	if dir != "." {
+		id, err := fc.GetOrCreate(dir)
+		if err != nil {
+			errs <- fmt.Errorf("creating folder for %s: %w", job.relPath, err)
+			// Proceed with no parent if folder creation fails.
+		} else {
+			parentID = id
+		}
 	}
-	defer resp.Body.Close()
 
-	respBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("reading response: %w", err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to create folder %s: %s", folderName, string(respBytes))
+	importPath := job.path
+	if assetExtensions != nil && uploader != nil {
+		rewritten, cleanup, err := RewriteAssetLinks(job.path, assetExtensions, uploader)
+		if err != nil {
+			errs <- fmt.Errorf("processing assets for %s: %w", job.relPath, err)
+			return
+		}
+		defer cleanup()
+		importPath = rewritten
 	}
 
-	var createResp CreateDocumentResponse
-	if err := json.Unmarshal(respBytes, &createResp); err != nil {
-		return "", fmt.Errorf("unmarshalling response: %w", err)
-	}
-	if !createResp.Ok {
-		return "", fmt.Errorf("failed to create folder %s: %s", folderName, string(respBytes))
+	if err := backend.ImportDocument(importPath, parentID); err != nil {
+		errs <- fmt.Errorf("importing %s: %w", job.relPath, err)
+		return
 	}
-	if debug {
-		log.Printf("Created folder '%s' with ID: %s", folderName, createResp.Data.Id)
+	if state != nil {
+		if err := state.MarkFileImported(job.relPath, job.hash); err != nil {
+			errs <- fmt.Errorf("persisting state for %s: %w", job.relPath, err)
+		}
 	}
-	return createResp.Data.Id, nil
 }
 
-// getOrCreateFolder returns the Outline document ID for the given relative folder path.
-// It will create folder documents for each segment as needed.
-func getOrCreateFolder(relPath, collectionId, host, token string) (string, error) {
-	// If the relative path is empty (or "."), then no parent.
-	if relPath == "" || relPath == "." {
-		return "", nil
-	}
-
-	// Normalize using forward slashes.
-	relPath = filepath.ToSlash(relPath)
+// runImport walks folder for Markdown files and imports them into backend
+// using a pool of concurrency workers. Files whose content hash matches
+// what's recorded in state are skipped. bar, if non-nil, is incremented
+// once per file (skipped or processed). assetExtensions being nil disables
+// asset upload/rewriting (-skip-assets).
+func runImport(folder string, concurrency int, backend Backend, fc *FolderCreator, state *StateStore, eventLog *EventLogger, bar *pb.ProgressBar, assetExtensions map[string]bool) []error {
+	jobs := make(chan fileJob)
+	errs := make(chan error)
+	var collected []error
+	var collectWg sync.WaitGroup
+	collectWg.Add(1)
+	go func() {
+		defer collectWg.Done()
+		for err := range errs {
+			eventLog.Error("", err)
+			collected = append(collected, err)
+		}
+	}()
 
-	// If already created, return the stored ID.
-	if id, ok := folderMap[relPath]; ok {
-		return id, nil
+	var workerWg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workerWg.Add(1)
+		go func() {
+			defer workerWg.Done()
+			importWorker(jobs, errs, fc, state, backend, bar, assetExtensions)
+		}()
 	}
 
-	// Split the path into segments and ensure each folder exists.
-	segments := strings.Split(relPath, "/")
-	var currentPath string
-	var parentID string // for the current segment (empty for top-level)
-	for _, seg := range segments {
-		if currentPath == "" {
-			currentPath = seg
-		} else {
-			currentPath = currentPath + "/" + seg
+	walkErr := filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
 		}
-		if id, exists := folderMap[currentPath]; exists {
-			parentID = id
-			continue
+		if !isMarkdownFile(info) {
+			return nil
 		}
-		newID, err := createFolderDocument(seg, collectionId, parentID, host, token)
+		relPath, err := filepath.Rel(folder, path)
 		if err != nil {
-			return "", fmt.Errorf("creating folder '%s': %w", currentPath, err)
+			return err
 		}
-		folderMap[currentPath] = newID
-		parentID = newID
-	}
-	return parentID, nil
-}
-
-// listCollections calls /api/collections.list and prints available collections.
-func listCollections(host, token string) error {
-	url := host + "/api/collections.list"
-	payload := map[string]interface{}{
-		"offset": 0,
-		"limit":  100,
-	}
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("marshalling payload: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
-
-	if debug {
-		log.Printf("Listing collections via %s", url)
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("executing request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("reading response: %w", err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to list collections: %s", string(respBytes))
-	}
-
-	var collectionsResp CollectionsResponse
-	if err := json.Unmarshal(respBytes, &collectionsResp); err != nil {
-		return fmt.Errorf("unmarshalling response: %w", err)
-	}
-
-	if !collectionsResp.Ok {
-		return fmt.Errorf("collections list not OK: %s", string(respBytes))
-	}
+		hash, err := hashFile(path)
+		if err != nil {
+			return fmt.Errorf("hashing %s: %w", path, err)
+		}
+		if state != nil {
+			if recorded, ok := state.FileHash(relPath); ok && recorded == hash {
+				if debug {
+					log.Printf("Skipping unchanged file: %s", path)
+				}
+				if bar != nil {
+					bar.Increment()
+				}
+				return nil
+			}
+		}
+		if debug {
+			log.Printf("Queueing Markdown file: %s", path)
+		}
+		jobs <- fileJob{path: path, relPath: relPath, hash: hash}
+		return nil
+	})
+	close(jobs)
+	workerWg.Wait()
+	close(errs)
+	collectWg.Wait()
 
-	fmt.Println("Collections:")
-	for _, col := range collectionsResp.Data {
-		fmt.Printf("ID: %s, Name: %s, Description: %s\n", col.Id, col.Name, col.Description)
+	if walkErr != nil {
+		collected = append(collected, fmt.Errorf("walking folder: %w", walkErr))
 	}
-	return nil
+	return collected
 }
 
 func main() {
@@ -280,67 +175,132 @@ func main() {
 	collectionPtr := flag.String("collection", "", "Valid collection UUID to import documents into")
 	tokenPtr := flag.String("token", "", "Outline API token")
 	listFlag := flag.Bool("list", false, "List collections and exit")
+	concurrencyPtr := flag.Int("concurrency", 4, "Number of files to import concurrently")
+	maxRetriesPtr := flag.Int("max-retries", 5, "Maximum retries per request on 429/5xx responses")
+	statePtr := flag.String("state", "", "Path to a state file used to make reruns resumable")
+	forcePtr := flag.Bool("force", false, "Ignore any existing state file and re-import everything")
+	resetPtr := flag.Bool("reset", false, "Wipe the state file before running")
+	silentPtr := flag.Bool("silent", false, "Suppress all output except errors")
+	noProgressPtr := flag.Bool("no-progress", false, "Disable the progress bar but keep line logs")
+	logFormatPtr := flag.String("log-format", "text", "Structured event log format: text or json")
+	backendPtr := flag.String("backend", "outline", "Import destination: outline, dry-run, or filesystem")
+	targetPtr := flag.String("target", "imported_output", "Destination directory for the filesystem backend")
+	skipAssetsPtr := flag.Bool("skip-assets", false, "Don't upload or rewrite local image/asset references")
+	assetExtensionsPtr := flag.String("asset-extensions", "png,jpg,jpeg,gif,pdf", "Comma-separated extensions treated as uploadable assets")
 	flag.BoolVar(&debug, "debug", false, "Enable debug logging")
 	flag.Parse()
 
-	// Use token from flag or environment.
-	token := *tokenPtr
-	if token == "" {
-		token = os.Getenv("OUTLINE_API_TOKEN")
-		if token == "" {
-			log.Fatal("Outline API token must be provided via -token flag or OUTLINE_API_TOKEN environment variable")
-		}
+	if *logFormatPtr != "text" && *logFormatPtr != "json" {
+		log.Fatalf("invalid -log-format %q: must be \"text\" or \"json\"", *logFormatPtr)
 	}
+	eventLog := NewEventLogger(os.Stdout, *logFormatPtr, *silentPtr)
 
-	// If -list is specified, list collections and exit.
-	if *listFlag {
-		if err := listCollections(*hostPtr, token); err != nil {
-			log.Fatalf("Error listing collections: %v", err)
-		}
-		return
+	if *concurrencyPtr < 1 {
+		log.Fatal("-concurrency must be at least 1")
 	}
-
-	// Ensure a valid collection UUID is provided.
-	if *collectionPtr == "" {
-		log.Fatal("A valid collection UUID must be provided via the -collection flag, or use -list to view collections")
+	if *maxRetriesPtr < 0 {
+		log.Fatal("-max-retries must not be negative")
 	}
 
-	// Walk the base folder recursively.
-	err := filepath.Walk(*folderPtr, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+	// Validate the requested backend and handle any flag combination that
+	// exits early (-list) or is outright invalid, before touching the state
+	// file below - state setup includes the destructive -reset/-force
+	// paths, which must not run on an invocation that's about to bail out.
+	var newBackend func(state *StateStore) Backend
+	switch *backendPtr {
+	case "outline":
+		// Use token from flag or environment.
+		token := *tokenPtr
+		if token == "" {
+			token = os.Getenv("OUTLINE_API_TOKEN")
+			if token == "" {
+				log.Fatal("Outline API token must be provided via -token flag or OUTLINE_API_TOKEN environment variable")
+			}
 		}
-		if info.IsDir() {
-			return nil
+		client := NewOutlineClient(10*time.Millisecond, 2*time.Second, *maxRetriesPtr, eventLog)
+
+		// If -list is specified, list collections and exit.
+		if *listFlag {
+			collections, err := client.ListCollections(*hostPtr, token)
+			if err != nil {
+				log.Fatalf("Error listing collections: %v", err)
+			}
+			fmt.Println("Collections:")
+			for _, col := range collections {
+				fmt.Printf("ID: %s, Name: %s, Description: %s\n", col.Id, col.Name, col.Description)
+			}
+			return
+		}
+
+		// Ensure a valid collection UUID is provided.
+		if *collectionPtr == "" {
+			log.Fatal("A valid collection UUID must be provided via the -collection flag, or use -list to view collections")
+		}
+		newBackend = func(state *StateStore) Backend {
+			return NewOutlineBackend(client, *collectionPtr, *hostPtr, token, state)
+		}
+	case "dry-run":
+		if *listFlag {
+			log.Fatal("-list is only supported with -backend=outline")
+		}
+		newBackend = func(state *StateStore) Backend { return NewDryRunBackend() }
+	case "filesystem":
+		if *listFlag {
+			log.Fatal("-list is only supported with -backend=outline")
 		}
-		if strings.HasSuffix(strings.ToLower(info.Name()), ".md") {
-			if debug {
-				log.Printf("Processing Markdown file: %s", path)
+		fsBackend, err := NewFilesystemBackend(*targetPtr)
+		if err != nil {
+			log.Fatalf("Error preparing filesystem backend: %v", err)
+		}
+		newBackend = func(state *StateStore) Backend { return fsBackend }
+	default:
+		log.Fatalf("invalid -backend %q: must be outline, dry-run, or filesystem", *backendPtr)
+	}
+
+	var state *StateStore
+	if *statePtr != "" {
+		if *resetPtr {
+			if err := os.Remove(*statePtr); err != nil && !os.IsNotExist(err) {
+				log.Fatalf("Error resetting state file: %v", err)
 			}
-			// Compute the file's relative path with respect to the base folder.
-			relPath, err := filepath.Rel(*folderPtr, path)
+			state = NewStateStore(*statePtr)
+		} else {
+			loaded, err := LoadStateStore(*statePtr)
 			if err != nil {
-				return err
-			}
-			// Get the directory part.
-			dir := filepath.Dir(relPath)
-			var parentID string
-			if dir != "." {
-				// Create (or retrieve) folder document(s) for this directory.
-				parentID, err = getOrCreateFolder(dir, *collectionPtr, *hostPtr, token)
-				if err != nil {
-					log.Printf("Error creating folder for %s: %v", dir, err)
-					// Proceed with no parent if folder creation fails.
-				}
+				log.Fatalf("Error loading state file: %v", err)
 			}
-			// Import the Markdown file with the determined parent document ID.
-			if err := importMarkdownFile(path, *collectionPtr, parentID, *hostPtr, token); err != nil {
-				log.Printf("Error importing file %s: %v", path, err)
+			if *forcePtr {
+				loaded.Clear()
 			}
+			state = loaded
 		}
-		return nil
-	})
-	if err != nil {
-		log.Fatalf("Error walking folder: %v", err)
+	}
+	backend := newBackend(state)
+
+	var bar *pb.ProgressBar
+	if !*silentPtr && !*noProgressPtr {
+		total, err := countMarkdownFiles(*folderPtr)
+		if err != nil {
+			log.Fatalf("Error counting Markdown files: %v", err)
+		}
+		bar = pb.StartNew(total)
+		bar.SetTemplate(pb.Full)
+	}
+
+	var assetExtensions map[string]bool
+	if !*skipAssetsPtr {
+		assetExtensions = ParseAssetExtensions(*assetExtensionsPtr)
+	}
+
+	fc := NewFolderCreator(backend, state)
+	errs := runImport(*folderPtr, *concurrencyPtr, backend, fc, state, eventLog, bar, assetExtensions)
+	if bar != nil {
+		bar.Finish()
+	}
+	if len(errs) > 0 {
+		if !*silentPtr {
+			log.Printf("Import finished with %d error(s)", len(errs))
+		}
+		os.Exit(1)
 	}
 }
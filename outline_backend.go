@@ -0,0 +1,104 @@
+package main
+
+import "sync"
+
+// OutlineBackend is the Backend implementation that talks to a real
+// Outline instance through an OutlineClient.
+type OutlineBackend struct {
+	client                    *OutlineClient
+	collectionId, host, token string
+	state                     *StateStore // optional; persists the asset cache across restarts
+
+	assetsMu   sync.Mutex
+	assets     map[string]string // content sha256 -> uploaded attachment URL
+	assetLocks sync.Map          // content sha256 -> *sync.Mutex, serializes concurrent uploads of the same file
+}
+
+// NewOutlineBackend returns a Backend that imports into the given Outline
+// collection. If state is non-nil, uploaded assets are recorded in it so a
+// resumed run doesn't re-upload files it already uploaded.
+func NewOutlineBackend(client *OutlineClient, collectionId, host, token string, state *StateStore) *OutlineBackend {
+	return &OutlineBackend{
+		client:       client,
+		collectionId: collectionId,
+		host:         host,
+		token:        token,
+		state:        state,
+		assets:       make(map[string]string),
+	}
+}
+
+func (b *OutlineBackend) CreateFolder(name, parentID string) (string, error) {
+	return b.client.CreateFolderDocument(name, b.collectionId, parentID, b.host, b.token)
+}
+
+func (b *OutlineBackend) ImportDocument(path, parentID string) error {
+	return b.client.ImportMarkdownFile(path, b.collectionId, parentID, b.host, b.token)
+}
+
+func (b *OutlineBackend) ListCollections() ([]Collection, error) {
+	return b.client.ListCollections(b.host, b.token)
+}
+
+// UploadAsset uploads path as an Outline attachment and returns its URL,
+// reusing a previous upload if a file with identical content has already
+// been uploaded (so an image referenced from ten documents is only sent
+// once). If a StateStore was provided, the upload is also checked against
+// and recorded in it, so a resumed run doesn't re-upload assets a prior,
+// killed attempt already uploaded.
+func (b *OutlineBackend) UploadAsset(path string) (string, error) {
+	hash, err := hashFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	lock, _ := b.assetLocks.LoadOrStore(hash, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if url, ok := b.cachedAssetURL(hash); ok {
+		return url, nil
+	}
+
+	url, err := b.client.UploadAttachment(path, b.host, b.token)
+	if err != nil {
+		return "", err
+	}
+
+	b.assetsMu.Lock()
+	b.assets[hash] = url
+	b.assetsMu.Unlock()
+	if b.state != nil {
+		// The upload itself succeeded; a failure to persist it is a
+		// resumability hiccup, not an upload failure, so it's logged rather
+		// than returned (matching how importJob treats a MarkFileImported
+		// failure after a successful ImportDocument).
+		if err := b.state.SetAsset(hash, url); err != nil && b.client.log != nil {
+			b.client.log.Error(path, err)
+		}
+	}
+	return url, nil
+}
+
+// cachedAssetURL returns a previously uploaded URL for hash, checking the
+// in-run cache first and falling back to the persisted state (if any).
+func (b *OutlineBackend) cachedAssetURL(hash string) (string, bool) {
+	b.assetsMu.Lock()
+	if url, ok := b.assets[hash]; ok {
+		b.assetsMu.Unlock()
+		return url, true
+	}
+	b.assetsMu.Unlock()
+
+	if b.state == nil {
+		return "", false
+	}
+	url, ok := b.state.AssetURL(hash)
+	if ok {
+		b.assetsMu.Lock()
+		b.assets[hash] = url
+		b.assetsMu.Unlock()
+	}
+	return url, ok
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Collection represents a collection in Outline.
+type Collection struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CreateDocumentResponse represents the response from /api/documents.create.
+type CreateDocumentResponse struct {
+	Data struct {
+		Id string `json:"id"`
+	} `json:"data"`
+	Ok bool `json:"ok"`
+}
+
+// CollectionsResponse represents the response from /api/collections.list.
+type CollectionsResponse struct {
+	Data []Collection `json:"data"`
+	Ok   bool         `json:"ok"`
+}
+
+// OutlineClient talks to the Outline API over a shared, connection-pooled
+// http.Client, pacing every request through a Pacer so that 429/5xx
+// responses back off instead of hammering the server.
+type OutlineClient struct {
+	http  *http.Client
+	pacer *Pacer
+	log   *EventLogger
+}
+
+// NewOutlineClient returns an OutlineClient with connection pooling enabled
+// and requests paced/retried according to minSleep/maxSleep/maxRetries.
+// Every request logs file_imported/folder_created/retry events to logger.
+func NewOutlineClient(minSleep, maxSleep time.Duration, maxRetries int, logger *EventLogger) *OutlineClient {
+	transport := &http.Transport{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 100,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &OutlineClient{
+		http:  &http.Client{Transport: transport},
+		pacer: NewPacer(minSleep, maxSleep, maxRetries),
+		log:   logger,
+	}
+}
+
+// ImportMarkdownFile uploads a Markdown file to Outline using
+// /api/documents.import. The file is imported with the given
+// parentDocumentId (if provided).
+func (c *OutlineClient) ImportMarkdownFile(filePath, collectionId, parentDocumentId, host, token string) error {
+	url := host + "/api/documents.import"
+	start := time.Now()
+
+	resp, err, attempts := c.pacer.Call(func() (*http.Response, error) {
+		// Rebuild the multipart body on every attempt: it's already been
+		// drained by the previous attempt's request.
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("opening file: %w", err)
+		}
+		defer file.Close()
+
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+
+		part, err := writer.CreateFormFile("file", filepath.Base(filePath))
+		if err != nil {
+			return nil, fmt.Errorf("creating form file: %w", err)
+		}
+		if _, err := io.Copy(part, file); err != nil {
+			return nil, fmt.Errorf("copying file content: %w", err)
+		}
+		if err := writer.WriteField("collectionId", collectionId); err != nil {
+			return nil, fmt.Errorf("writing collectionId field: %w", err)
+		}
+		if parentDocumentId != "" {
+			if err := writer.WriteField("parentDocumentId", parentDocumentId); err != nil {
+				return nil, fmt.Errorf("writing parentDocumentId field: %w", err)
+			}
+		}
+		if err := writer.WriteField("template", "false"); err != nil {
+			return nil, fmt.Errorf("writing template field: %w", err)
+		}
+		if err := writer.WriteField("publish", "true"); err != nil {
+			return nil, fmt.Errorf("writing publish field: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return nil, fmt.Errorf("closing writer: %w", err)
+		}
+
+		req, err := http.NewRequest("POST", url, &buf)
+		if err != nil {
+			return nil, fmt.Errorf("creating HTTP request: %w", err)
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		if debug {
+			log.Printf("Importing file: %s (parent: %s) to %s", filePath, parentDocumentId, url)
+		}
+		return c.http.Do(req)
+	}, func(attempt int, reason string) {
+		if c.log != nil {
+			c.log.Retry(filePath, attempt, reason)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("executing HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to import %s: %s", filePath, string(body))
+	}
+
+	if debug {
+		log.Printf("Imported file: %s, response: %s", filePath, string(body))
+	}
+	if c.log != nil {
+		c.log.FileImported(filePath, parentDocumentId, time.Since(start), attempts)
+	}
+	return nil
+}
+
+// CreateFolderDocument creates a "folder" document in Outline using
+// /api/documents.create. The folder is represented as a document with a
+// title (folderName) and empty text. Only include parentDocumentId if it's
+// not empty.
+func (c *OutlineClient) CreateFolderDocument(folderName, collectionId, parentDocumentId, host, token string) (string, error) {
+	url := host + "/api/documents.create"
+	start := time.Now()
+
+	payload := map[string]interface{}{
+		"collectionId": collectionId,
+		"title":        folderName,
+		"text":         "",
+		"template":     false,
+		"publish":      false,
+	}
+	if parentDocumentId != "" {
+		payload["parentDocumentId"] = parentDocumentId
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("marshalling payload: %w", err)
+	}
+
+	resp, err, attempts := c.pacer.Call(func() (*http.Response, error) {
+		req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		if debug {
+			log.Printf("Creating folder document: %s (parent: %s) via %s", folderName, parentDocumentId, url)
+		}
+		return c.http.Do(req)
+	}, func(attempt int, reason string) {
+		if c.log != nil {
+			c.log.Retry(folderName, attempt, reason)
+		}
+	})
+	if err != nil {
+		return "", fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to create folder %s: %s", folderName, string(respBytes))
+	}
+
+	var createResp CreateDocumentResponse
+	if err := json.Unmarshal(respBytes, &createResp); err != nil {
+		return "", fmt.Errorf("unmarshalling response: %w", err)
+	}
+	if !createResp.Ok {
+		return "", fmt.Errorf("failed to create folder %s: %s", folderName, string(respBytes))
+	}
+	if debug {
+		log.Printf("Created folder '%s' with ID: %s", folderName, createResp.Data.Id)
+	}
+	if c.log != nil {
+		c.log.FolderCreated(folderName, parentDocumentId, time.Since(start), attempts)
+	}
+	return createResp.Data.Id, nil
+}
+
+// ListCollections calls /api/collections.list and returns the available
+// collections.
+func (c *OutlineClient) ListCollections(host, token string) ([]Collection, error) {
+	url := host + "/api/collections.list"
+	payload := map[string]interface{}{
+		"offset": 0,
+		"limit":  100,
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling payload: %w", err)
+	}
+
+	resp, err, _ := c.pacer.Call(func() (*http.Response, error) {
+		req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		if debug {
+			log.Printf("Listing collections via %s", url)
+		}
+		return c.http.Do(req)
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list collections: %s", string(respBytes))
+	}
+
+	var collectionsResp CollectionsResponse
+	if err := json.Unmarshal(respBytes, &collectionsResp); err != nil {
+		return nil, fmt.Errorf("unmarshalling response: %w", err)
+	}
+	if !collectionsResp.Ok {
+		return nil, fmt.Errorf("collections list not OK: %s", string(respBytes))
+	}
+	return collectionsResp.Data, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Pacer paces HTTP calls against a rate-limited API, backing off
+// exponentially on 429/5xx responses and decaying back down on success.
+// The algorithm mirrors rclone's lib/pacer: a shared delay is doubled on
+// failure (capped at maxSleep) and halved on success (floored at minSleep),
+// so a burst of errors from one goroutine slows down every caller sharing
+// the Pacer.
+type Pacer struct {
+	mu         sync.Mutex
+	sleepTime  time.Duration
+	minSleep   time.Duration
+	maxSleep   time.Duration
+	maxRetries int
+}
+
+// NewPacer returns a Pacer starting at minSleep, doubling on failure up to
+// maxSleep, and retrying an operation up to maxRetries times. A negative
+// maxRetries is treated as 0, since Call must always attempt fn at least
+// once.
+func NewPacer(minSleep, maxSleep time.Duration, maxRetries int) *Pacer {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	return &Pacer{
+		sleepTime:  minSleep,
+		minSleep:   minSleep,
+		maxSleep:   maxSleep,
+		maxRetries: maxRetries,
+	}
+}
+
+// beginCall sleeps for the current shared delay (plus jitter) before letting
+// a caller proceed.
+func (p *Pacer) beginCall() {
+	p.mu.Lock()
+	sleep := p.sleepTime
+	p.mu.Unlock()
+	if sleep <= 0 {
+		return
+	}
+	// Add up to 10% jitter so concurrent goroutines don't wake in lockstep.
+	jitter := time.Duration(rand.Int63n(int64(sleep)/10 + 1))
+	time.Sleep(sleep + jitter)
+}
+
+// reportSuccess halves the shared delay, flooring at minSleep.
+func (p *Pacer) reportSuccess() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sleepTime /= 2
+	if p.sleepTime < p.minSleep {
+		p.sleepTime = p.minSleep
+	}
+}
+
+// reportFailure doubles the shared delay, capping at maxSleep, and returns
+// the amount of time the caller should additionally wait before retrying
+// (honoring retryAfter if the server provided one).
+func (p *Pacer) reportFailure(retryAfter time.Duration) time.Duration {
+	p.mu.Lock()
+	p.sleepTime *= 2
+	if p.sleepTime > p.maxSleep {
+		p.sleepTime = p.maxSleep
+	}
+	wait := p.sleepTime
+	p.mu.Unlock()
+	if retryAfter > wait {
+		wait = retryAfter
+	}
+	return wait
+}
+
+// shouldRetry reports whether a response warrants a retry under the pacer's
+// policy: 429 and any 5xx status.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	if resp == nil {
+		return false
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryAfter parses the Retry-After header, supporting both delay-seconds
+// and HTTP-date forms. It returns zero if the header is absent or invalid.
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	h := resp.Header.Get("Retry-After")
+	if h == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(h); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// Call runs fn, applying pacing delays and retrying on 429/5xx responses
+// (or transport errors) up to the pacer's maxRetries. fn must perform a
+// single HTTP round-trip and return the response (which Call does not
+// close) alongside any transport error. onRetry, if non-nil, is invoked
+// once per retry with the 1-based attempt number that just failed and a
+// description of why. Call returns the total number of attempts made (1 if
+// it succeeded first try).
+func (p *Pacer) Call(fn func() (*http.Response, error), onRetry func(attempt int, reason string)) (*http.Response, error, int) {
+	var resp *http.Response
+	var err error
+	for attempt := 0; attempt <= p.maxRetries; attempt++ {
+		p.beginCall()
+		resp, err = fn()
+		if !shouldRetry(resp, err) {
+			p.reportSuccess()
+			return resp, err, attempt + 1
+		}
+		if attempt == p.maxRetries {
+			break
+		}
+		wait := p.reportFailure(retryAfter(resp))
+		if onRetry != nil {
+			onRetry(attempt+1, retryReason(resp, err))
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		time.Sleep(wait)
+	}
+	return resp, err, p.maxRetries + 1
+}
+
+// retryReason describes why a response or error triggered a retry.
+func retryReason(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("HTTP %d", resp.StatusCode)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func respWithStatus(code int) *http.Response {
+	return &http.Response{StatusCode: code, Body: http.NoBody}
+}
+
+func TestPacerCall_SucceedsFirstTry(t *testing.T) {
+	p := NewPacer(time.Millisecond, 10*time.Millisecond, 3)
+	calls := 0
+	resp, err, attempts := p.Call(func() (*http.Response, error) {
+		calls++
+		return respWithStatus(http.StatusOK), nil
+	}, func(attempt int, reason string) {
+		t.Fatalf("unexpected retry: attempt=%d reason=%s", attempt, reason)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 1 || calls != 1 {
+		t.Fatalf("attempts=%d calls=%d, want 1 and 1", attempts, calls)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("resp.StatusCode = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestPacerCall_RetriesOn429ThenSucceeds(t *testing.T) {
+	p := NewPacer(time.Millisecond, 10*time.Millisecond, 3)
+	calls := 0
+	var retries []string
+	resp, err, attempts := p.Call(func() (*http.Response, error) {
+		calls++
+		if calls < 3 {
+			return respWithStatus(http.StatusTooManyRequests), nil
+		}
+		return respWithStatus(http.StatusOK), nil
+	}, func(attempt int, reason string) {
+		retries = append(retries, reason)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 3 || calls != 3 {
+		t.Fatalf("attempts=%d calls=%d, want 3 and 3", attempts, calls)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("resp.StatusCode = %d, want 200", resp.StatusCode)
+	}
+	wantRetries := []string{"HTTP 429", "HTTP 429"}
+	if len(retries) != len(wantRetries) {
+		t.Fatalf("retries = %v, want %v", retries, wantRetries)
+	}
+	for i, r := range retries {
+		if r != wantRetries[i] {
+			t.Fatalf("retries[%d] = %q, want %q", i, r, wantRetries[i])
+		}
+	}
+}
+
+func TestNewPacer_NegativeMaxRetriesCallsAtLeastOnce(t *testing.T) {
+	// A negative maxRetries must not make the `attempt <= maxRetries` loop
+	// in Call skip fn entirely, which would return a nil response with a
+	// nil error and panic every caller's defer resp.Body.Close().
+	p := NewPacer(time.Millisecond, 10*time.Millisecond, -1)
+	calls := 0
+	resp, err, attempts := p.Call(func() (*http.Response, error) {
+		calls++
+		return respWithStatus(http.StatusOK), nil
+	}, func(attempt int, reason string) {})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 || attempts != 1 {
+		t.Fatalf("calls=%d attempts=%d, want 1 and 1", calls, attempts)
+	}
+	if resp == nil {
+		t.Fatal("resp is nil, want a response from a single call to fn")
+	}
+}
+
+func TestPacerCall_ExhaustsMaxRetries(t *testing.T) {
+	p := NewPacer(time.Millisecond, 10*time.Millisecond, 2)
+	calls := 0
+	resp, err, attempts := p.Call(func() (*http.Response, error) {
+		calls++
+		return respWithStatus(http.StatusServiceUnavailable), nil
+	}, func(attempt int, reason string) {})
+	if err != nil {
+		t.Fatalf("unexpected transport error: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("resp.StatusCode = %d, want 503", resp.StatusCode)
+	}
+	// maxRetries=2 means 1 initial attempt + 2 retries = 3 calls total.
+	if attempts != 3 || calls != 3 {
+		t.Fatalf("attempts=%d calls=%d, want 3 and 3", attempts, calls)
+	}
+}
+
+func TestPacerSuccessAndFailure_GrowAndDecaySleepTime(t *testing.T) {
+	p := NewPacer(time.Millisecond, 100*time.Millisecond, 5)
+
+	p.reportFailure(0)
+	p.reportFailure(0)
+	p.mu.Lock()
+	grown := p.sleepTime
+	p.mu.Unlock()
+	if grown != 4*time.Millisecond {
+		t.Fatalf("sleepTime after two failures = %v, want 4ms", grown)
+	}
+
+	p.reportSuccess()
+	p.mu.Lock()
+	decayed := p.sleepTime
+	p.mu.Unlock()
+	if decayed != 2*time.Millisecond {
+		t.Fatalf("sleepTime after one success = %v, want 2ms", decayed)
+	}
+}
+
+func TestPacerReportFailure_CapsAtMaxSleep(t *testing.T) {
+	p := NewPacer(time.Millisecond, 3*time.Millisecond, 5)
+	for i := 0; i < 10; i++ {
+		p.reportFailure(0)
+	}
+	p.mu.Lock()
+	sleep := p.sleepTime
+	p.mu.Unlock()
+	if sleep != 3*time.Millisecond {
+		t.Fatalf("sleepTime = %v, want capped at 3ms", sleep)
+	}
+}
+
+func TestPacerReportSuccess_FloorsAtMinSleep(t *testing.T) {
+	p := NewPacer(5*time.Millisecond, 100*time.Millisecond, 5)
+	p.reportSuccess()
+	p.mu.Lock()
+	sleep := p.sleepTime
+	p.mu.Unlock()
+	if sleep != 5*time.Millisecond {
+		t.Fatalf("sleepTime = %v, want floored at 5ms", sleep)
+	}
+}
+
+func TestPacerReportFailure_HonorsRetryAfterOverSleepTime(t *testing.T) {
+	p := NewPacer(time.Millisecond, 2*time.Millisecond, 5)
+	wait := p.reportFailure(50 * time.Millisecond)
+	if wait != 50*time.Millisecond {
+		t.Fatalf("wait = %v, want 50ms (Retry-After should win over the smaller backoff)", wait)
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	cases := []struct {
+		name string
+		resp *http.Response
+		err  error
+		want bool
+	}{
+		{"transport error", nil, errTransport, true},
+		{"429", respWithStatus(http.StatusTooManyRequests), nil, true},
+		{"500", respWithStatus(http.StatusInternalServerError), nil, true},
+		{"503", respWithStatus(http.StatusServiceUnavailable), nil, true},
+		{"200", respWithStatus(http.StatusOK), nil, false},
+		{"404", respWithStatus(http.StatusNotFound), nil, false},
+		{"nil resp no error", nil, nil, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := shouldRetry(c.resp, c.err); got != c.want {
+				t.Fatalf("shouldRetry() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Run("delay seconds", func(t *testing.T) {
+		resp := respWithStatus(http.StatusTooManyRequests)
+		resp.Header = http.Header{"Retry-After": []string{"2"}}
+		if got := retryAfter(resp); got != 2*time.Second {
+			t.Fatalf("retryAfter() = %v, want 2s", got)
+		}
+	})
+	t.Run("http date", func(t *testing.T) {
+		future := time.Now().Add(5 * time.Second)
+		resp := respWithStatus(http.StatusTooManyRequests)
+		resp.Header = http.Header{"Retry-After": []string{future.UTC().Format(http.TimeFormat)}}
+		got := retryAfter(resp)
+		if got <= 0 || got > 5*time.Second {
+			t.Fatalf("retryAfter() = %v, want a positive duration up to ~5s", got)
+		}
+	})
+	t.Run("missing header", func(t *testing.T) {
+		resp := respWithStatus(http.StatusTooManyRequests)
+		if got := retryAfter(resp); got != 0 {
+			t.Fatalf("retryAfter() = %v, want 0", got)
+		}
+	})
+	t.Run("past http date", func(t *testing.T) {
+		resp := respWithStatus(http.StatusTooManyRequests)
+		resp.Header = http.Header{"Retry-After": []string{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)}}
+		if got := retryAfter(resp); got != 0 {
+			t.Fatalf("retryAfter() = %v, want 0 for a past date", got)
+		}
+	})
+	t.Run("nil response", func(t *testing.T) {
+		if got := retryAfter(nil); got != 0 {
+			t.Fatalf("retryAfter(nil) = %v, want 0", got)
+		}
+	})
+}
+
+type stubTransportError struct{}
+
+func (stubTransportError) Error() string { return "stub transport error" }
+
+var errTransport = stubTransportError{}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// State is the on-disk, persisted form of an import run: the Outline
+// document ID created for each relative folder path, and the content hash
+// of each relative file path that was successfully imported.
+type State struct {
+	Folders map[string]string `json:"folders"`
+	Files   map[string]string `json:"files"`  // relative path -> sha256 hex digest
+	Assets  map[string]string `json:"assets"` // content sha256 -> uploaded attachment URL
+}
+
+// StateStore guards a State with a mutex and persists it to path after
+// every mutation, so a killed process loses at most the in-flight call.
+type StateStore struct {
+	path string
+
+	mu    sync.Mutex
+	state State
+}
+
+// NewStateStore returns an empty StateStore that will persist to path.
+func NewStateStore(path string) *StateStore {
+	return &StateStore{
+		path: path,
+		state: State{
+			Folders: make(map[string]string),
+			Files:   make(map[string]string),
+			Assets:  make(map[string]string),
+		},
+	}
+}
+
+// LoadStateStore reads state from path if it exists, returning an empty
+// StateStore if it does not.
+func LoadStateStore(path string) (*StateStore, error) {
+	s := NewStateStore(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if len(data) == 0 {
+		return s, nil
+	}
+	if err := json.Unmarshal(data, &s.state); err != nil {
+		return nil, err
+	}
+	if s.state.Folders == nil {
+		s.state.Folders = make(map[string]string)
+	}
+	if s.state.Files == nil {
+		s.state.Files = make(map[string]string)
+	}
+	if s.state.Assets == nil {
+		s.state.Assets = make(map[string]string)
+	}
+	return s, nil
+}
+
+// Clear discards in-memory folder/file progress (what -force re-imports)
+// without touching the file on disk; the next successful call will
+// overwrite the file with fresh state. The asset-upload cache is kept,
+// since -force re-imports documents, not unchanged image content, and
+// clearing it would force every shared asset to be re-uploaded.
+func (s *StateStore) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = State{
+		Folders: make(map[string]string),
+		Files:   make(map[string]string),
+		Assets:  s.state.Assets,
+	}
+}
+
+// Folders returns a copy of the relative-folder-path -> document-ID map.
+func (s *StateStore) Folders() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]string, len(s.state.Folders))
+	for k, v := range s.state.Folders {
+		out[k] = v
+	}
+	return out
+}
+
+// SetFolder records that relPath maps to the given Outline document ID and
+// persists the update.
+func (s *StateStore) SetFolder(relPath, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Folders[relPath] = id
+	return s.save()
+}
+
+// FileHash returns the recorded content hash for relPath, if any.
+func (s *StateStore) FileHash(relPath string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.state.Files[relPath]
+	return h, ok
+}
+
+// MarkFileImported records that relPath was successfully imported with the
+// given content hash and persists the update.
+func (s *StateStore) MarkFileImported(relPath, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Files[relPath] = hash
+	return s.save()
+}
+
+// AssetURL returns the previously recorded upload URL for the asset with
+// the given content hash, if any.
+func (s *StateStore) AssetURL(hash string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	url, ok := s.state.Assets[hash]
+	return url, ok
+}
+
+// SetAsset records that the asset with the given content hash was uploaded
+// to url and persists the update, so a resumed run doesn't re-upload it.
+func (s *StateStore) SetAsset(hash, url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Assets[hash] = url
+	return s.save()
+}
+
+// save writes the current state to s.path atomically (write to a temp file,
+// then rename). Callers must hold s.mu.
+func (s *StateStore) save() error {
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// hashFile returns the hex-encoded sha256 digest of the file at path.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStateStore_SaveAndLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s := NewStateStore(path)
+	if err := s.SetFolder("a/b", "folder-id-1"); err != nil {
+		t.Fatalf("SetFolder returned error: %v", err)
+	}
+	if err := s.MarkFileImported("a/b/doc.md", "deadbeef"); err != nil {
+		t.Fatalf("MarkFileImported returned error: %v", err)
+	}
+	if err := s.SetAsset("content-hash-1", "https://example.com/attachments/foo.png"); err != nil {
+		t.Fatalf("SetAsset returned error: %v", err)
+	}
+
+	loaded, err := LoadStateStore(path)
+	if err != nil {
+		t.Fatalf("LoadStateStore returned error: %v", err)
+	}
+	if got := loaded.Folders()["a/b"]; got != "folder-id-1" {
+		t.Fatalf("Folders()[\"a/b\"] = %q, want %q", got, "folder-id-1")
+	}
+	hash, ok := loaded.FileHash("a/b/doc.md")
+	if !ok || hash != "deadbeef" {
+		t.Fatalf("FileHash(\"a/b/doc.md\") = (%q, %v), want (%q, true)", hash, ok, "deadbeef")
+	}
+	assetURL, ok := loaded.AssetURL("content-hash-1")
+	if !ok || assetURL != "https://example.com/attachments/foo.png" {
+		t.Fatalf("AssetURL(\"content-hash-1\") = (%q, %v), want (%q, true)", assetURL, ok, "https://example.com/attachments/foo.png")
+	}
+}
+
+func TestLoadStateStore_MissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "does-not-exist.json")
+
+	s, err := LoadStateStore(path)
+	if err != nil {
+		t.Fatalf("LoadStateStore returned error: %v", err)
+	}
+	if len(s.Folders()) != 0 {
+		t.Fatalf("Folders() = %v, want empty", s.Folders())
+	}
+	if _, ok := s.FileHash("anything"); ok {
+		t.Fatalf("FileHash should report not-found for a missing file")
+	}
+}
+
+func TestLoadStateStore_EmptyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadStateStore(path)
+	if err != nil {
+		t.Fatalf("LoadStateStore returned error: %v", err)
+	}
+	// Folders/Files must be usable maps, not nil, even when the file is empty.
+	if err := s.SetFolder("x", "id"); err != nil {
+		t.Fatalf("SetFolder on state loaded from an empty file returned error: %v", err)
+	}
+}
+
+func TestStateStore_Save_WritesAtomically(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s := NewStateStore(path)
+	if err := s.SetFolder("a", "id-a"); err != nil {
+		t.Fatalf("SetFolder returned error: %v", err)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected state file to exist after save: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("expected the .tmp file to be renamed away, stat error = %v", err)
+	}
+}
+
+func TestStateStore_Clear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s := NewStateStore(path)
+	if err := s.SetFolder("a", "id-a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.MarkFileImported("a/doc.md", "hash1"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Clear()
+	if len(s.Folders()) != 0 {
+		t.Fatalf("Folders() after Clear = %v, want empty", s.Folders())
+	}
+	if _, ok := s.FileHash("a/doc.md"); ok {
+		t.Fatalf("FileHash after Clear should report not-found")
+	}
+
+	// The on-disk file is untouched by Clear until the next save.
+	loaded, err := LoadStateStore(path)
+	if err != nil {
+		t.Fatalf("LoadStateStore returned error: %v", err)
+	}
+	if loaded.Folders()["a"] != "id-a" {
+		t.Fatalf("Clear should not rewrite the file on disk; loaded Folders() = %v", loaded.Folders())
+	}
+
+	// But the next mutation persists the cleared state, matching -force.
+	if err := s.SetFolder("b", "id-b"); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadStateStore(path)
+	if err != nil {
+		t.Fatalf("LoadStateStore returned error: %v", err)
+	}
+	if _, ok := reloaded.Folders()["a"]; ok {
+		t.Fatalf("expected \"a\" to be gone after Clear + save, got %v", reloaded.Folders())
+	}
+	if reloaded.Folders()["b"] != "id-b" {
+		t.Fatalf("expected \"b\" to be persisted after Clear + save, got %v", reloaded.Folders())
+	}
+}
+
+func TestStateStore_Clear_PreservesAssetCache(t *testing.T) {
+	// -force re-imports documents, not unchanged image content, so Clear
+	// must not force every shared asset to be re-uploaded.
+	path := filepath.Join(t.TempDir(), "state.json")
+	s := NewStateStore(path)
+	if err := s.SetAsset("hash1", "https://example.com/attachments/hash1"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Clear()
+	url, ok := s.AssetURL("hash1")
+	if !ok || url != "https://example.com/attachments/hash1" {
+		t.Fatalf("AssetURL(\"hash1\") after Clear = (%q, %v), want the asset cache preserved", url, ok)
+	}
+}
+
+func TestLoadStateStore_ResetRemovesFile(t *testing.T) {
+	// Mirrors main's -reset handling: remove the file, then start fresh.
+	path := filepath.Join(t.TempDir(), "state.json")
+	s := NewStateStore(path)
+	if err := s.SetFolder("a", "id-a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("removing state file returned error: %v", err)
+	}
+	fresh := NewStateStore(path)
+	if len(fresh.Folders()) != 0 {
+		t.Fatalf("Folders() on a fresh store = %v, want empty", fresh.Folders())
+	}
+
+	if err := fresh.SetFolder("b", "id-b"); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadStateStore(path)
+	if err != nil {
+		t.Fatalf("LoadStateStore returned error: %v", err)
+	}
+	if _, ok := reloaded.Folders()["a"]; ok {
+		t.Fatalf("expected reset to discard prior state, got %v", reloaded.Folders())
+	}
+	if reloaded.Folders()["b"] != "id-b" {
+		t.Fatalf("expected post-reset state to persist, got %v", reloaded.Folders())
+	}
+}